@@ -1,10 +1,14 @@
 package node
 
 import (
+	"fmt"
 	"github.com/dobyte/due/v2/cluster"
+	"github.com/dobyte/due/v2/log"
 	"github.com/dobyte/due/v2/utils/xcall"
+	"reflect"
 	"sync"
 	"sync/atomic"
+	"time"
 )
 
 type Creator func(actor *Actor, args ...any) Processor
@@ -16,16 +20,28 @@ const (
 )
 
 type Actor struct {
-	opts      *actorOptions                  // 配置项
-	scheduler *Scheduler                     // 调度器
-	state     atomic.Int32                   // 状态
-	routes    map[int32]RouteHandler         // 路由处理器
-	events    map[cluster.Event]EventHandler // 事件处理器
-	processor Processor                      // 处理器
-	rw        sync.RWMutex                   // 锁
-	mailbox   chan Context                   // 邮箱
-	fnChan    chan func()                    // 调用函数
-	binds     sync.Map                       // 绑定的用户
+	opts       *actorOptions                   // 配置项
+	scheduler  *Scheduler                      // 调度器
+	state      atomic.Int32                    // 状态
+	routes     map[int32]RouteHandler          // 路由处理器
+	events     map[cluster.Event]EventHandler  // 事件处理器
+	messages   map[reflect.Type]MessageHandler // Tell/Ask消息处理器
+	processor  Processor                       // 处理器
+	rw         sync.RWMutex                    // 锁
+	mailbox    Mailbox                         // 邮箱
+	fnChan     chan func()                     // 调用函数
+	binds      sync.Map                        // 绑定的用户
+	creator    Creator                         // 创建器，用于重启时重建处理器
+	args       []any                           // 创建参数
+	supervisor supervisorOptions               // 监督策略配置
+	parent     *Actor                          // 父Actor，nil表示根Actor
+	children   sync.Map                        // 子Actor集合，key为子Actor的ID
+	restarts   int32                           // 已重启次数
+	components []ComponentShutdowner           // 已注册组件中实现了OnShutdown的部分
+
+	persistMu                  sync.Mutex // 保护persistVersion/persistEventsSinceSnapshot
+	persistVersion             uint64     // 当前已持久化到的事件版本号
+	persistEventsSinceSnapshot int        // 距离上一次快照累计的事件数
 }
 
 // ID 获取Actor的ID
@@ -43,9 +59,35 @@ func (a *Actor) Kind() string {
 	return a.processor.Kind()
 }
 
-// Spawn 衍生出一个Actor
-func (a *Actor) Spawn(creator Creator, opts ...ActorOption) (*Actor, error) {
-	return a.scheduler.spawn(creator, opts...)
+// MailboxStats 获取当前Actor的邮箱运行指标（队列深度、丢弃数、平均处理耗时等）
+func (a *Actor) MailboxStats() MailboxStats {
+	return a.mailbox.Stats()
+}
+
+// Spawn 衍生出一个Actor，child会被记录为当前Actor的子Actor，
+// 默认采用OneForOne监督策略，可通过supervisorOpts覆盖。args仅在监督策略触发重启时
+// 原样透传给creator；初次创建由scheduler.spawn完成，creator不会收到args
+func (a *Actor) Spawn(creator Creator, args []any, opts []ActorOption, supervisorOpts ...SupervisorOption) (*Actor, error) {
+	// scheduler.spawn负责在启动dispatch前就用opts中的mailboxCapacity/overflowPolicy/mailboxPriority
+	// 构造好child.mailbox，这里不能等spawn返回后再重新赋值一个mailbox——dispatch的goroutine
+	// 届时已经在读取旧的child.mailbox，post-hoc重建既是对该字段的数据竞争，也会让spawn内部
+	// 创建的mailbox（及其run()协程）被孤立丢弃，期间投递的消息随之丢失
+	child, err := a.scheduler.spawn(creator, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	child.supervisor = defaultSupervisorOptions
+	for _, opt := range supervisorOpts {
+		opt(&child.supervisor)
+	}
+
+	child.parent = a
+	child.creator = creator
+	child.args = args
+	a.children.Store(child.ID(), child)
+
+	return child, nil
 }
 
 // Proxy 获取代理API
@@ -113,7 +155,7 @@ func (a *Actor) Next(ctx Context) {
 
 	ctx.Cancel()
 
-	a.mailbox <- ctx
+	a.mailbox.Post(ctx)
 }
 
 // Deliver 投递消息到Actor中进行处理
@@ -134,8 +176,18 @@ func (a *Actor) Destroy() {
 		return
 	}
 
+	pids.Delete(a.PID())
+
 	a.processor.Destroy()
 
+	if hook, ok := a.processor.(PostStopper); ok {
+		hook.OnPostStop()
+	}
+
+	for _, comp := range a.components {
+		comp.OnShutdown()
+	}
+
 	a.scheduler.batchUnbindActor(func(relations map[int64]map[string]*Actor) {
 		a.binds.Range(func(uid, _ any) bool {
 			delete(relations[uid.(int64)], a.Kind())
@@ -143,14 +195,103 @@ func (a *Actor) Destroy() {
 		})
 	})
 
+	if a.parent != nil {
+		a.parent.children.Delete(a.ID())
+	}
+
+	a.children.Range(func(_, child any) bool {
+		child.(*Actor).Destroy()
+		return true
+	})
+
 	a.rw.Lock()
 	defer a.rw.Unlock()
 
-	close(a.mailbox)
+	a.mailbox.Close()
 
 	close(a.fnChan)
 }
 
+// restart 按照监督策略重建处理器：清空路由/事件/消息处理器，以原始creator和args重建Processor
+// 并重新执行其Init，binds不受影响。调用方必须确保restart在该Actor自己的dispatch线程上执行
+func (a *Actor) restart(cause error) {
+	if a.creator == nil {
+		log.Errorf("actor %s: cannot restart, creator is nil", a.PID())
+		return
+	}
+
+	atomic.AddInt32(&a.restarts, 1)
+
+	time.Sleep(a.supervisor.backoff(int(atomic.LoadInt32(&a.restarts)) - 1))
+
+	a.rw.Lock()
+	// 暂时退回unstart状态，使creator在构造Processor过程中通过AddRouteHandler/AddEventHandler
+	// 注册的处理器直接写入map，而不是投递到当前已暂停消费的fnChan导致死锁
+	a.state.Store(unstart)
+	a.routes = make(map[int32]RouteHandler)
+	a.events = make(map[cluster.Event]EventHandler)
+	a.messages = make(map[reflect.Type]MessageHandler)
+	a.components = nil
+	// creator/Init中的AddRouteHandler等会获取a.rw读锁，因此必须在释放写锁后再调用
+	a.rw.Unlock()
+
+	a.processor = a.creator(a, a.args...)
+	a.processor.Init()
+
+	a.state.Store(started)
+
+	if hook, ok := a.processor.(PreStarter); ok {
+		if err := hook.OnPreStart(); err != nil {
+			log.Errorf("actor %s restart failed after panic %v: %v", a.PID(), cause, err)
+		}
+	}
+}
+
+// handlePanic 根据监督策略处理dispatch循环中捕获到的panic，返回值表示Actor是否应当继续存活
+func (a *Actor) handlePanic(err error, ctx Context) bool {
+	if hook, ok := a.processor.(Paniker); ok {
+		hook.OnPanic(err, ctx)
+	}
+
+	switch a.supervisor.strategy {
+	case StopOnFailure:
+		a.Destroy()
+		return false
+	case EscalateToParent:
+		if a.parent != nil {
+			// parent.handlePanic可能触发parent.restart，后者必须在parent自己的dispatch线程上
+			// 执行，因此与OneForAll的兄弟重启一样通过Invoke marshal过去，而不是从当前子Actor的
+			// dispatch goroutine直接调用
+			a.parent.Invoke(func() { a.parent.handlePanic(err, ctx) })
+		}
+		a.Destroy()
+		return false
+	case OneForAll:
+		if a.parent != nil {
+			a.parent.children.Range(func(_, sibling any) bool {
+				if s := sibling.(*Actor); s != a {
+					// 兄弟Actor的processor/routes/events只能在其自身的dispatch线程上安全改写，
+					// 因此通过其fnChan marshal重启，而不是从当前panic的goroutine直接调用
+					s.Invoke(func() { s.restart(err) })
+				}
+				return true
+			})
+		}
+		a.restart(err)
+		return true
+	case RestartOnFailure:
+		if int(atomic.LoadInt32(&a.restarts)) >= a.supervisor.maxRestarts {
+			a.Destroy()
+			return false
+		}
+		a.restart(err)
+		return true
+	default: // OneForOne
+		a.restart(err)
+		return true
+	}
+}
+
 // 绑定用户
 func (a *Actor) bindUser(uid int64) {
 	a.binds.Store(uid, struct{}{})
@@ -164,29 +305,47 @@ func (a *Actor) unbindUser(uid int64) bool {
 
 // 分发
 func (a *Actor) dispatch() {
+	pids.Store(a.PID(), a)
+
+	if err := a.recover(); err != nil {
+		// 事件存储恢复失败：状态只是空的/局部的时间线，绝不能把邮箱开放给外界当成
+		// 一个正常启动的Actor——那样下一次Persist会把版本1写穿已有的事件日志。
+		// 宁可拒绝启动，也不要带着损坏的状态跑起来
+		log.Errorf("actor %s: recovery failed, refusing to start: %v", a.PID(), err)
+		pids.Delete(a.PID())
+		a.state.Store(destroyed)
+		return
+	}
+
+	if hook, ok := a.processor.(PreStarter); ok {
+		if err := hook.OnPreStart(); err != nil {
+			log.Errorf("actor %s OnPreStart failed: %v", a.PID(), err)
+		}
+	}
+
 	go func() {
 		for {
 			select {
-			case ctx, ok := <-a.mailbox:
+			case ctx, ok := <-a.mailbox.Next():
 				if !ok {
 					return
 				}
 
+				start := time.Now()
+
 				version := ctx.loadVersion()
 
-				if ctx.Kind() == Event {
-					if handler, ok := a.events[ctx.Event()]; ok {
-						xcall.Call(func() { handler(ctx) })
-					}
-				} else {
-					if handler, ok := a.routes[ctx.Route()]; ok {
-						xcall.Call(func() { handler(ctx) })
-					}
-				}
+				alive := a.dispatchCtx(ctx)
+
+				a.mailbox.Done(start)
 
 				ctx.compareVersionExecDefer(version)
 
 				ctx.compareVersionRecycle(version)
+
+				if !alive {
+					return
+				}
 			case handle, ok := <-a.fnChan:
 				if !ok {
 					return
@@ -196,3 +355,27 @@ func (a *Actor) dispatch() {
 		}
 	}()
 }
+
+// dispatchCtx 处理单条消息，捕获处理过程中的panic并交由监督策略处理，
+// 返回值表示处理完成后Actor是否应当继续存活
+func (a *Actor) dispatchCtx(ctx Context) (alive bool) {
+	alive = true
+
+	defer func() {
+		if r := recover(); r != nil {
+			alive = a.handlePanic(fmt.Errorf("%v", r), ctx)
+		}
+	}()
+
+	if ctx.Kind() == Event {
+		if handler, ok := a.events[ctx.Event()]; ok {
+			xcall.Call(func() { handler(ctx) })
+		}
+	} else {
+		if handler, ok := a.routes[ctx.Route()]; ok {
+			xcall.Call(func() { handler(ctx) })
+		}
+	}
+
+	return
+}
@@ -0,0 +1,149 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"github.com/dobyte/due/v2/log"
+	"reflect"
+	"sync"
+)
+
+// Tell/Ask/PipeTo是进程内Actor间的类型化请求/响应机制，仅能寻址当前进程pids表中登记的
+// 本地PID，不跨节点：PID（Kind/ID）本身不携带节点标识，因此无法判断一个PID是否归属于
+// 其他节点，自然也就没有"转发给远端节点"这一说。需要跨节点通信时请改用
+// Proxy.Deliver/Actor.Deliver + Proxy.Response这一套既有的、以uid绑定关系路由的
+// 网络投递机制，而不是本文件中的PID寻址。
+var (
+	// ErrActorNotFound 目标Actor未找到或尚未启动（仅检查当前进程本地的pids表，不跨节点）
+	ErrActorNotFound = errors.New("node: actor not found (local-only lookup, not found in this process)")
+	// ErrNoMessageHandler 目标Actor未注册对应消息类型的处理器
+	ErrNoMessageHandler = errors.New("node: no message handler registered for this message type")
+)
+
+// pids 进程内PID到Actor的映射，供Tell/Ask按PID路由，在dispatch启动时注册、Destroy时移除
+var pids sync.Map
+
+// MessageHandler 处理通过Tell/Ask投递的消息，返回值作为Ask的响应
+type MessageHandler func(ctx context.Context, from string, msg any) (any, error)
+
+// AddMessageHandler 为某一种消息类型注册处理器，Tell/Ask会依据msg的动态类型查找对应处理器
+func (a *Actor) AddMessageHandler(msg any, handler MessageHandler) {
+	a.rw.RLock()
+	defer a.rw.RUnlock()
+
+	typ := reflect.TypeOf(msg)
+
+	switch a.state.Load() {
+	case unstart:
+		a.ensureMessages()
+		a.messages[typ] = handler
+	case started:
+		a.fnChan <- func() {
+			a.ensureMessages()
+			a.messages[typ] = handler
+		}
+	default:
+		// ignore
+	}
+}
+
+func (a *Actor) ensureMessages() {
+	if a.messages == nil {
+		a.messages = make(map[reflect.Type]MessageHandler)
+	}
+}
+
+// Tell 向pid对应的Actor发送一条消息，不等待处理结果。仅能寻址本进程内的PID，跨节点通信
+// 请改用Proxy.Deliver
+func (a *Actor) Tell(pid string, msg any) error {
+	target, ok := lookupActor(pid)
+	if !ok {
+		return ErrActorNotFound
+	}
+
+	from := a.PID()
+
+	target.Invoke(func() {
+		handler, ok := target.messages[reflect.TypeOf(msg)]
+		if !ok {
+			return
+		}
+
+		if _, err := handler(context.Background(), from, msg); err != nil {
+			log.Errorf("actor %s handle message from %s failed: %v", target.PID(), from, err)
+		}
+	})
+
+	return nil
+}
+
+// Ask 向pid对应的Actor发送一条消息，并阻塞等待其处理完成后的响应，超时或取消由ctx控制。
+// 目标Actor在其自身的fnChan（即单线程执行队列）上处理该消息，处理函数内无需加锁。
+// 仅能寻址本进程内的PID：pid归属于其他节点时返回ErrActorNotFound，跨节点的请求/响应
+// 请改用Proxy.Deliver与Proxy.Response
+func Ask[T any](ctx context.Context, from *Actor, pid string, msg any) (T, error) {
+	var zero T
+
+	target, ok := lookupActor(pid)
+	if !ok {
+		return zero, ErrActorNotFound
+	}
+
+	result := make(chan any, 1)
+	fail := make(chan error, 1)
+
+	fromPID := ""
+	if from != nil {
+		fromPID = from.PID()
+	}
+
+	target.Invoke(func() {
+		handler, ok := target.messages[reflect.TypeOf(msg)]
+		if !ok {
+			fail <- ErrNoMessageHandler
+			return
+		}
+
+		rsp, err := handler(ctx, fromPID, msg)
+		if err != nil {
+			fail <- err
+			return
+		}
+
+		result <- rsp
+	})
+
+	select {
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	case err := <-fail:
+		return zero, err
+	case rsp := <-result:
+		v, ok := rsp.(T)
+		if !ok {
+			return zero, errors.New("node: ask response type mismatch")
+		}
+		return v, nil
+	}
+}
+
+// PipeTo 异步执行Ask，并将结果通过from的fnChan回调给onComplete，保证onComplete在from所在的
+// Actor线程上执行，调用方无需加锁
+func PipeTo[T any](ctx context.Context, from *Actor, pid string, msg any, onComplete func(T, error)) {
+	go func() {
+		rsp, err := Ask[T](ctx, from, pid, msg)
+
+		from.Invoke(func() {
+			onComplete(rsp, err)
+		})
+	}()
+}
+
+func lookupActor(pid string) (*Actor, bool) {
+	v, ok := pids.Load(pid)
+	if !ok {
+		return nil, false
+	}
+
+	return v.(*Actor), true
+}
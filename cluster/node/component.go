@@ -0,0 +1,155 @@
+package node
+
+import (
+	"fmt"
+	"github.com/dobyte/due/v2/log"
+	"hash/fnv"
+	"reflect"
+)
+
+// Component 组件基类，用户自定义组件内嵌该结构即可获得Actor()访问能力，
+// 并可选实现OnInit/OnShutdown钩子接入组件的生命周期
+type Component struct {
+	actor *Actor
+}
+
+// Actor 获取组件所挂载的Actor
+func (c *Component) Actor() *Actor {
+	return c.actor
+}
+
+func (c *Component) setActor(actor *Actor) {
+	c.actor = actor
+}
+
+// ComponentIniter 可选钩子，组件注册成功后立即被调用
+type ComponentIniter interface {
+	OnInit()
+}
+
+// ComponentShutdowner 可选钩子，组件所挂载的Actor销毁时被调用
+type ComponentShutdowner interface {
+	OnShutdown()
+}
+
+// ComponentOption 组件注册配置项
+type ComponentOption func(o *componentOptions)
+
+type componentOptions struct {
+	serviceName   string
+	methodNameFn  func(methodName string) string
+	routeMapperFn func(methodName string) int32
+}
+
+// WithServiceName 设置组件对应的服务名，仅用于日志与诊断，默认取组件的反射类型名
+func WithServiceName(name string) ComponentOption {
+	return func(o *componentOptions) { o.serviceName = name }
+}
+
+// WithMethodNameFunc 设置方法名到路由名的转换规则，默认原样使用Go方法名
+func WithMethodNameFunc(fn func(methodName string) string) ComponentOption {
+	return func(o *componentOptions) { o.methodNameFn = fn }
+}
+
+// WithRouteMapper 设置方法名到路由号的映射规则，默认对方法名做FNV-32哈希
+func WithRouteMapper(fn func(methodName string) int32) ComponentOption {
+	return func(o *componentOptions) { o.routeMapperFn = fn }
+}
+
+func defaultRouteMapper(methodName string) int32 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(methodName))
+	return int32(h.Sum32())
+}
+
+// RegisterComponent 通过反射扫描comp上形如
+// func (c *C) Login(ctx node.Context, req *LoginReq) (*LoginRsp, error)
+// 的导出方法，并将其注册为actor的路由处理器，解码、调用、回包均由框架完成
+func RegisterComponent(actor *Actor, comp any, opts ...ComponentOption) error {
+	o := componentOptions{routeMapperFn: defaultRouteMapper}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	rv := reflect.ValueOf(comp)
+	rt := rv.Type()
+
+	if o.serviceName == "" {
+		o.serviceName = rt.String()
+	}
+
+	if base, ok := comp.(interface{ setActor(*Actor) }); ok {
+		base.setActor(actor)
+	}
+
+	ctxType := reflect.TypeOf((*Context)(nil)).Elem()
+	errType := reflect.TypeOf((*error)(nil)).Elem()
+
+	registered := 0
+
+	for i := 0; i < rt.NumMethod(); i++ {
+		m := rt.Method(i)
+
+		if m.Type.NumIn() != 3 || m.Type.NumOut() != 2 {
+			continue
+		}
+
+		if m.Type.In(1) != ctxType {
+			continue
+		}
+
+		if m.Type.In(2).Kind() != reflect.Ptr {
+			continue
+		}
+
+		if m.Type.Out(0).Kind() != reflect.Ptr || !m.Type.Out(1).Implements(errType) {
+			continue
+		}
+
+		method := m
+		reqType := m.Type.In(2)
+
+		methodName := method.Name
+		if o.methodNameFn != nil {
+			methodName = o.methodNameFn(methodName)
+		}
+
+		route := o.routeMapperFn(methodName)
+
+		actor.AddRouteHandler(route, func(ctx Context) {
+			req := reflect.New(reqType.Elem())
+
+			if err := actor.scheduler.node.opts.codec.Unmarshal(ctx.Request().Message.Data, req.Interface()); err != nil {
+				log.Errorf("component %s decode request for method %s failed: %v", o.serviceName, method.Name, err)
+				return
+			}
+
+			out := method.Func.Call([]reflect.Value{rv, reflect.ValueOf(ctx), req})
+
+			if !out[1].IsNil() {
+				log.Errorf("component %s handle method %s failed: %v", o.serviceName, method.Name, out[1].Interface())
+				return
+			}
+
+			if err := actor.Proxy().Response(ctx.Context(), ctx.Request(), out[0].Interface()); err != nil {
+				log.Errorf("component %s response method %s failed: %v", o.serviceName, method.Name, err)
+			}
+		})
+
+		registered++
+	}
+
+	if registered == 0 {
+		return fmt.Errorf("node: no route method found on component %s", o.serviceName)
+	}
+
+	if initer, ok := comp.(ComponentIniter); ok {
+		initer.OnInit()
+	}
+
+	if shutdowner, ok := comp.(ComponentShutdowner); ok {
+		actor.components = append(actor.components, shutdowner)
+	}
+
+	return nil
+}
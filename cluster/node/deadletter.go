@@ -0,0 +1,40 @@
+package node
+
+import "sync"
+
+// deadLetterSink 节点级死信队列，邮箱按DeadLetter策略丢弃的消息最终都会汇聚到这里，
+// 供外部通过Proxy.SubscribeDeadLetters订阅
+type deadLetterSink struct {
+	in   chan Context
+	rw   sync.RWMutex
+	subs []chan<- Context
+}
+
+func newDeadLetterSink() *deadLetterSink {
+	s := &deadLetterSink{in: make(chan Context, 64)}
+
+	go s.dispatch()
+
+	return s
+}
+
+func (s *deadLetterSink) dispatch() {
+	for ctx := range s.in {
+		s.rw.RLock()
+		for _, sub := range s.subs {
+			select {
+			case sub <- ctx:
+			default:
+				// 订阅者处理不过来时丢弃，避免阻塞死信分发
+			}
+		}
+		s.rw.RUnlock()
+	}
+}
+
+func (s *deadLetterSink) subscribe(ch chan<- Context) {
+	s.rw.Lock()
+	defer s.rw.Unlock()
+
+	s.subs = append(s.subs, ch)
+}
@@ -0,0 +1,552 @@
+package node
+
+import (
+	"context"
+	"github.com/dobyte/due/v2/cluster"
+	"github.com/dobyte/due/v2/log"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemberState 成员在gossip视角下的健康状态，独立于cluster.State，用于SWIM故障探测
+type MemberState int
+
+const (
+	MemberAlive MemberState = iota
+	MemberSuspect
+	MemberDead
+)
+
+// Member 成员摘要，gossip以该结构为粒度在节点间同步
+type Member struct {
+	NID         string        // 节点ID
+	Name        string        // 服务名
+	State       cluster.State // 业务状态
+	Incarnation uint64        // 版本号，成员每次自证存活时递增，用于压制过期的suspect/dead传闻
+	Heartbeat   int64         // 最近一次心跳的unix纳秒时间戳
+	gossip      MemberState   // gossip视角下的健康状态
+}
+
+// GossipEvent 成员状态变更事件，通过SubscribeMembership对外广播
+type GossipEvent struct {
+	Member Member
+	Joined bool // true表示成员加入或恢复存活，false表示被标记为suspect/dead
+}
+
+// UserEventType 用户绑定类增量事件的类型
+type UserEventType int
+
+const (
+	UserBindGate UserEventType = iota
+	UserUnbindGate
+	UserBindNode
+	UserUnbindNode
+)
+
+// UserEvent 用户绑定类增量事件（BindGate/BindNode/UnbindNode），随gossip消息一并传播
+type UserEvent struct {
+	NID string // 产生该事件的节点ID
+	Seq uint64 // 该节点内的单调递增序号，用于seen-set去重
+	Typ UserEventType
+	UID int64
+	Key string // name+nid 之类的定位键
+}
+
+// membershipDigest 一轮gossip交换携带的负载：成员摘要 + 待回放的用户事件增量
+type membershipDigest struct {
+	Members []Member
+	Events  []UserEvent
+}
+
+// GossipTransport 负责把membershipDigest发送给目标节点并取回对方的摘要，
+// 未配置时gossip退化为仅依赖registry/locator的冷启动模式
+type GossipTransport interface {
+	Exchange(ctx context.Context, targetNID string, digest *membershipDigest) (*membershipDigest, error)
+	// ProbePeer 请求viaNID代为探测targetNID是否存活，用于SWIM的间接探测（indirect probe），
+	// viaNID不可达或探测失败时返回false
+	ProbePeer(ctx context.Context, viaNID, targetNID string) (bool, error)
+}
+
+// GossipOption gossip子系统配置项
+type GossipOption func(o *gossipOptions)
+
+type gossipOptions struct {
+	interval     time.Duration // 每轮gossip的间隔T
+	fanout       int           // 每轮选取的随机对等节点数K
+	suspectAfter int           // 心跳连续缺失suspectAfter个周期后转入suspect
+	seenLimit    int           // 用户事件seen-set的LRU容量上限
+	transport    GossipTransport
+}
+
+var defaultGossipOptions = gossipOptions{
+	interval:     time.Second,
+	fanout:       3,
+	suspectAfter: 3,
+	seenLimit:    4096,
+}
+
+// WithGossipInterval 设置每轮gossip的间隔T
+func WithGossipInterval(d time.Duration) GossipOption {
+	return func(o *gossipOptions) { o.interval = d }
+}
+
+// WithGossipFanout 设置每轮随机选取的对等节点数K
+func WithGossipFanout(k int) GossipOption {
+	return func(o *gossipOptions) { o.fanout = k }
+}
+
+// WithGossipSuspectAfter 设置心跳连续缺失多少个周期后转入suspect状态
+func WithGossipSuspectAfter(rounds int) GossipOption {
+	return func(o *gossipOptions) { o.suspectAfter = rounds }
+}
+
+// WithGossipTransport 设置gossip消息的传输实现，不设置时退化为仅依赖registry的冷启动模式
+func WithGossipTransport(transport GossipTransport) GossipOption {
+	return func(o *gossipOptions) { o.transport = transport }
+}
+
+// userBindings 反熵同步得到的某个用户的绑定关系本地视图
+type userBindings struct {
+	gate  string            // 绑定的网关ID，空表示未绑定
+	nodes map[string]string // 绑定的节点，name -> nid
+}
+
+// gossiper 节点内运行的SWIM风格成员发现与反熵传播器
+type gossiper struct {
+	opts gossipOptions
+	nid  string
+
+	rw      sync.RWMutex
+	members map[string]*Member
+
+	seq     uint64
+	seenMu  sync.Mutex
+	seen    map[string]struct{}
+	seenLRU []string
+	pending []UserEvent
+
+	bindsMu sync.RWMutex
+	binds   map[int64]*userBindings
+
+	subMu sync.RWMutex
+	subs  []chan<- GossipEvent
+}
+
+func newGossiper(nid string, opts ...GossipOption) *gossiper {
+	o := defaultGossipOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return &gossiper{
+		opts:    o,
+		nid:     nid,
+		members: make(map[string]*Member),
+		seen:    make(map[string]struct{}),
+		binds:   make(map[int64]*userBindings),
+	}
+}
+
+// seed 用registry/locator拉取到的当前成员列表初始化冷启动状态
+func (g *gossiper) seed(members []Member) {
+	g.rw.Lock()
+	defer g.rw.Unlock()
+
+	for i := range members {
+		m := members[i]
+		m.Heartbeat = time.Now().UnixNano()
+		m.gossip = MemberAlive
+		g.members[m.NID] = &m
+	}
+}
+
+// publish 记录一条本节点产生的用户事件增量，等待随下一轮gossip piggyback出去，
+// 并立即应用到本地绑定视图
+func (g *gossiper) publish(typ UserEventType, uid int64, key string) UserEvent {
+	g.seenMu.Lock()
+	g.seq++
+	e := UserEvent{NID: g.nid, Seq: g.seq, Typ: typ, UID: uid, Key: key}
+	g.pending = append(g.pending, e)
+	g.markSeenLocked(eventKey(e))
+	g.seenMu.Unlock()
+
+	g.applyUserEvent(e)
+
+	return e
+}
+
+func eventKey(e UserEvent) string {
+	return e.NID + "#" + strconv.Itoa(int(e.Typ)) + "#" + strconv.FormatInt(e.UID, 10) + "#" + e.Key
+}
+
+// applyUserEvent 将一条用户绑定增量事件应用到本地绑定视图，使BindNode/BindGate等操作
+// 经由反熵传播后，其他节点也能观察到一致的绑定结果
+func (g *gossiper) applyUserEvent(e UserEvent) {
+	g.bindsMu.Lock()
+	defer g.bindsMu.Unlock()
+
+	b, ok := g.binds[e.UID]
+	if !ok {
+		b = &userBindings{nodes: make(map[string]string)}
+		g.binds[e.UID] = b
+	}
+
+	switch e.Typ {
+	case UserBindGate:
+		b.gate = e.Key
+	case UserUnbindGate:
+		b.gate = ""
+	case UserBindNode:
+		if name, nid, ok := splitNameNID(e.Key); ok {
+			b.nodes[name] = nid
+		}
+	case UserUnbindNode:
+		if name, nid, ok := splitNameNID(e.Key); ok && b.nodes[name] == nid {
+			delete(b.nodes, name)
+		}
+	}
+}
+
+// splitNameNID 还原BindNode/UnbindNode中以"name/nid"形式拼接的key
+func splitNameNID(key string) (name, nid string, ok bool) {
+	name, nid, ok = strings.Cut(key, "/")
+	return
+}
+
+// locateGate 返回反熵视图中uid当前绑定的网关ID
+func (g *gossiper) locateGate(uid int64) (string, bool) {
+	g.bindsMu.RLock()
+	defer g.bindsMu.RUnlock()
+
+	b, ok := g.binds[uid]
+	if !ok || b.gate == "" {
+		return "", false
+	}
+
+	return b.gate, true
+}
+
+// locateNode 返回反熵视图中uid在name对应服务下绑定的节点ID
+func (g *gossiper) locateNode(uid int64, name string) (string, bool) {
+	g.bindsMu.RLock()
+	defer g.bindsMu.RUnlock()
+
+	b, ok := g.binds[uid]
+	if !ok {
+		return "", false
+	}
+
+	nid, ok := b.nodes[name]
+
+	return nid, ok
+}
+
+func (g *gossiper) markSeenLocked(key string) {
+	if _, ok := g.seen[key]; ok {
+		return
+	}
+
+	g.seen[key] = struct{}{}
+	g.seenLRU = append(g.seenLRU, key)
+
+	if len(g.seenLRU) > g.opts.seenLimit {
+		oldest := g.seenLRU[0]
+		g.seenLRU = g.seenLRU[1:]
+		delete(g.seen, oldest)
+	}
+}
+
+// run 启动定时gossip循环，每T毫秒挑选K个随机对等节点交换摘要
+func (g *gossiper) run(ctx context.Context) {
+	ticker := time.NewTicker(g.opts.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			g.tick(ctx)
+		}
+	}
+}
+
+func (g *gossiper) tick(ctx context.Context) {
+	g.touchSelfHeartbeat()
+
+	g.detectSuspects()
+
+	if g.opts.transport != nil {
+		g.probeSuspects(ctx)
+	}
+
+	peers := g.randomPeers(g.opts.fanout)
+
+	if g.opts.transport == nil || len(peers) == 0 {
+		return
+	}
+
+	digest := g.buildDigest()
+
+	for _, peer := range peers {
+		reply, err := g.opts.transport.Exchange(ctx, peer, digest)
+		if err != nil {
+			log.Errorf("gossip exchange with %s failed: %v", peer, err)
+			continue
+		}
+
+		g.touchHeartbeat(peer)
+
+		g.merge(reply)
+	}
+}
+
+// touchSelfHeartbeat 刷新本节点在成员表中的心跳时间戳，使其随下一轮摘要扩散给其他节点，
+// 是心跳存活判定得以工作的前提——否则其他节点永远无法获知本节点的最新存活时间
+func (g *gossiper) touchSelfHeartbeat() {
+	g.rw.Lock()
+	defer g.rw.Unlock()
+
+	self, ok := g.members[g.nid]
+	if !ok {
+		self = &Member{NID: g.nid}
+		g.members[g.nid] = self
+	}
+
+	self.Heartbeat = time.Now().UnixNano()
+	self.gossip = MemberAlive
+}
+
+// refuteLocked 处理merge时收到的、关于本节点自身的摘要条目：reported是其他节点眼中的本节点
+// 状态，一旦它不是alive或携带了高于本节点当前的incarnation，本节点就刷新心跳重新自证存活；
+// 若其incarnation不低于本节点当前值，则将本节点incarnation提升为其值+1，才能在后续的摘要
+// 交换中盖过并压制这条过期的suspect/dead传闻——否则该传闻会在incarnation持平的情况下继续
+// 依赖心跳比较，迟迟压不住。调用方需持有g.rw
+func (g *gossiper) refuteLocked(reported Member) {
+	self, ok := g.members[g.nid]
+	if !ok {
+		self = &Member{NID: g.nid}
+		g.members[g.nid] = self
+	}
+
+	if reported.gossip == MemberAlive && reported.Incarnation <= self.Incarnation {
+		return
+	}
+
+	if reported.Incarnation >= self.Incarnation {
+		self.Incarnation = reported.Incarnation + 1
+	}
+
+	self.Heartbeat = time.Now().UnixNano()
+	self.gossip = MemberAlive
+
+	g.broadcastLocked(GossipEvent{Member: *self, Joined: true})
+}
+
+// touchHeartbeat 标记一次对nid的直接探测（Exchange）成功，刷新其心跳并在必要时将其从
+// suspect恢复为alive
+func (g *gossiper) touchHeartbeat(nid string) {
+	g.rw.Lock()
+	defer g.rw.Unlock()
+
+	m, ok := g.members[nid]
+	if !ok {
+		return
+	}
+
+	m.Heartbeat = time.Now().UnixNano()
+
+	if m.gossip != MemberAlive {
+		m.gossip = MemberAlive
+		g.broadcastLocked(GossipEvent{Member: *m, Joined: true})
+	}
+}
+
+// detectSuspects 根据心跳陈旧程度将成员从alive转入suspect，不处理已在suspect中的成员
+// （它们交由probeSuspects做进一步的间接探测与最终裁定）
+func (g *gossiper) detectSuspects() {
+	g.rw.Lock()
+	defer g.rw.Unlock()
+
+	for _, m := range g.members {
+		if m.NID == g.nid || m.gossip != MemberAlive {
+			continue
+		}
+
+		if time.Since(time.Unix(0, m.Heartbeat)) > g.opts.interval*time.Duration(g.opts.suspectAfter) {
+			m.gossip = MemberSuspect
+			g.broadcastLocked(GossipEvent{Member: *m, Joined: false})
+		}
+	}
+}
+
+// probeSuspects 对仍处于suspect状态的成员发起间接探测：委托K个其他成员代为探测，
+// 任意一个确认存活即恢复为alive，否则判定为dead并从成员表中移除
+func (g *gossiper) probeSuspects(ctx context.Context) {
+	g.rw.RLock()
+	suspects := make([]string, 0)
+	for nid, m := range g.members {
+		if m.gossip == MemberSuspect {
+			suspects = append(suspects, nid)
+		}
+	}
+	g.rw.RUnlock()
+
+	for _, nid := range suspects {
+		if g.indirectlyReachable(ctx, nid) {
+			g.touchHeartbeat(nid)
+			continue
+		}
+
+		g.rw.Lock()
+		m, ok := g.members[nid]
+		if ok {
+			m.gossip = MemberDead
+			delete(g.members, nid)
+		}
+		g.rw.Unlock()
+
+		if ok {
+			g.broadcastLocked(GossipEvent{Member: *m, Joined: false})
+		}
+	}
+}
+
+// indirectlyReachable 委托K个(不含target自身)成员代为探测target是否存活
+func (g *gossiper) indirectlyReachable(ctx context.Context, target string) bool {
+	helpers := g.randomPeersExcluding(g.opts.fanout, target)
+
+	for _, helper := range helpers {
+		alive, err := g.opts.transport.ProbePeer(ctx, helper, target)
+		if err == nil && alive {
+			return true
+		}
+	}
+
+	return false
+}
+
+// buildDigest 构建本轮待发送的摘要，pending中的事件在被取出后即视为已piggyback，
+// 不再重复累积；后续是否需要再次传播完全依赖seen-set对收到事件的去重
+func (g *gossiper) buildDigest() *membershipDigest {
+	g.rw.RLock()
+	members := make([]Member, 0, len(g.members))
+	for _, m := range g.members {
+		members = append(members, *m)
+	}
+	g.rw.RUnlock()
+
+	g.seenMu.Lock()
+	events := g.pending
+	g.pending = nil
+	g.seenMu.Unlock()
+
+	return &membershipDigest{Members: members, Events: events}
+}
+
+// merge 合并一次gossip交换返回的摘要：成员以incarnation/heartbeat较新的为准，事件按seen-set去重
+func (g *gossiper) merge(digest *membershipDigest) {
+	if digest == nil {
+		return
+	}
+
+	g.rw.Lock()
+	for _, m := range digest.Members {
+		if m.NID == g.nid {
+			// 对方携带的是关于"本节点"的条目，不能被当成权威数据接受——本节点显然是存活的，
+			// 任何suspect/dead的传闻在此刻都已过期，需要自证存活来压制它继续扩散
+			g.refuteLocked(m)
+			continue
+		}
+
+		cur, ok := g.members[m.NID]
+		if !ok || m.Incarnation > cur.Incarnation || (m.Incarnation == cur.Incarnation && m.Heartbeat > cur.Heartbeat) {
+			changed := !ok || cur.gossip != MemberAlive
+			m.gossip = MemberAlive
+			g.members[m.NID] = &m
+			if changed {
+				g.broadcastLocked(GossipEvent{Member: m, Joined: true})
+			}
+		}
+	}
+	g.rw.Unlock()
+
+	fresh := make([]UserEvent, 0, len(digest.Events))
+
+	g.seenMu.Lock()
+	for _, e := range digest.Events {
+		key := eventKey(e)
+		if _, ok := g.seen[key]; ok {
+			continue
+		}
+		g.markSeenLocked(key)
+		g.pending = append(g.pending, e)
+		fresh = append(fresh, e)
+	}
+	g.seenMu.Unlock()
+
+	// 将首次见到的增量事件应用到本地绑定视图，真正实现绑定关系的反熵传播，
+	// 而不仅仅是把事件转发给下一轮gossip
+	for _, e := range fresh {
+		g.applyUserEvent(e)
+	}
+}
+
+func (g *gossiper) broadcastLocked(event GossipEvent) {
+	g.subMu.RLock()
+	defer g.subMu.RUnlock()
+
+	for _, sub := range g.subs {
+		select {
+		case sub <- event:
+		default:
+		}
+	}
+}
+
+func (g *gossiper) subscribe(ch chan<- GossipEvent) {
+	g.subMu.Lock()
+	defer g.subMu.Unlock()
+
+	g.subs = append(g.subs, ch)
+}
+
+func (g *gossiper) randomPeers(k int) []string {
+	return g.randomPeersExcluding(k, "")
+}
+
+// randomPeersExcluding 随机选取最多k个成员，排除自身、exclude以及已判定为dead的成员
+func (g *gossiper) randomPeersExcluding(k int, exclude string) []string {
+	g.rw.RLock()
+	defer g.rw.RUnlock()
+
+	candidates := make([]string, 0, len(g.members))
+	for nid, m := range g.members {
+		if nid != g.nid && nid != exclude && m.gossip != MemberDead {
+			candidates = append(candidates, nid)
+		}
+	}
+
+	rand.Shuffle(len(candidates), func(i, j int) { candidates[i], candidates[j] = candidates[j], candidates[i] })
+
+	if k > len(candidates) {
+		k = len(candidates)
+	}
+
+	return candidates[:k]
+}
+
+func (g *gossiper) list() []Member {
+	g.rw.RLock()
+	defer g.rw.RUnlock()
+
+	out := make([]Member, 0, len(g.members))
+	for _, m := range g.members {
+		out = append(out, *m)
+	}
+
+	return out
+}
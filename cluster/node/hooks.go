@@ -0,0 +1,16 @@
+package node
+
+// PreStarter 可选钩子，Processor实现该接口后会在Actor分发循环启动前被调用
+type PreStarter interface {
+	OnPreStart() error
+}
+
+// PostStopper 可选钩子，Processor实现该接口后会在Actor销毁过程中、Processor.Destroy执行之后被调用
+type PostStopper interface {
+	OnPostStop()
+}
+
+// Paniker 可选钩子，Processor实现该接口后会在处理消息发生panic时、监督策略执行之前被调用
+type Paniker interface {
+	OnPanic(err error, ctx Context)
+}
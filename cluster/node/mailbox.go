@@ -0,0 +1,272 @@
+package node
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// 优先级类别，数值越大优先级越高
+const (
+	PriorityRequest int = iota // 普通请求，默认优先级
+	PriorityEvent              // 事件通知
+	PrioritySystem             // 系统消息（如监督重启、销毁），最高优先级
+)
+
+// OverflowPolicy 邮箱容量溢出时的处理策略
+type OverflowPolicy int
+
+const (
+	OverflowBlock       OverflowPolicy = iota // 阻塞，直到邮箱有空闲位置
+	OverflowDropNewest                        // 丢弃即将投递的新消息
+	OverflowDropOldest                        // 丢弃最低优先级非空队列中最旧的消息
+	OverflowDeadLetter                        // 投递到死信队列
+)
+
+// MailboxStats 邮箱运行指标
+type MailboxStats struct {
+	Depth        int64         // 当前队列深度
+	Dropped      int64         // 累计丢弃数量
+	DeadLettered int64         // 累计进入死信队列的数量
+	AvgLatency   time.Duration // 最近一次统计的平均处理耗时
+}
+
+// Mailbox 邮箱接口，负责消息的投递、取出与积压指标统计
+type Mailbox interface {
+	// Post 投递一条消息，具体行为由溢出策略决定
+	Post(ctx Context)
+	// Next 返回用于在dispatch循环中select的消费通道
+	Next() <-chan Context
+	// Done 标记一条消息处理完成，用于统计平均处理耗时
+	Done(start time.Time)
+	// Stats 返回当前邮箱指标快照
+	Stats() MailboxStats
+	// Close 关闭邮箱，释放底层通道
+	Close()
+}
+
+// priorityFunc 从消息中计算优先级，数值越大越先被处理
+type priorityFunc func(ctx Context) int
+
+// SystemContext 可选接口，Context实现该接口且IsSystem返回true时会被邮箱视为系统消息
+// （如监督重启、销毁通知等生命周期控制指令），获得高于Event/Request的投递优先级
+type SystemContext interface {
+	IsSystem() bool
+}
+
+func defaultPriorityFunc(ctx Context) int {
+	if sc, ok := ctx.(SystemContext); ok && sc.IsSystem() {
+		return PrioritySystem
+	}
+	if ctx.Kind() == Event {
+		return PriorityEvent
+	}
+	return PriorityRequest
+}
+
+// priorityMailbox 默认的Mailbox实现，按优先级维护System/Event/Request三条有界队列，
+// 消费时优先取高优先级队列中的消息
+type priorityMailbox struct {
+	rw       sync.Mutex
+	capacity int
+	policy   OverflowPolicy
+	priority priorityFunc
+	queues   [3][]Context // 下标对应PriorityRequest/PriorityEvent/PrioritySystem
+	out      chan Context
+	closed   bool
+	notify   chan struct{} // 有新消息入队或Close时唤醒run，避免空闲轮询
+	done     chan struct{} // Close时关闭，唤醒阻塞在out上的run
+	deadCh   chan<- Context
+	notFull  *sync.Cond // OverflowBlock策略下，容量满时Post在此等待，run消费一条或Close时唤醒
+
+	depth        atomic.Int64
+	dropped      atomic.Int64
+	deadLettered atomic.Int64
+	totalLatency atomic.Int64
+	totalCount   atomic.Int64
+}
+
+// newPriorityMailbox 创建一个优先级邮箱，deadCh为nil时死信策略退化为丢弃
+func newPriorityMailbox(capacity int, policy OverflowPolicy, priority priorityFunc, deadCh chan<- Context) *priorityMailbox {
+	if priority == nil {
+		priority = defaultPriorityFunc
+	}
+
+	m := &priorityMailbox{
+		capacity: capacity,
+		policy:   policy,
+		priority: priority,
+		out:      make(chan Context),
+		notify:   make(chan struct{}, 1),
+		done:     make(chan struct{}),
+		deadCh:   deadCh,
+	}
+	m.notFull = sync.NewCond(&m.rw)
+
+	go m.run()
+
+	return m
+}
+
+// queuedLocked 返回三条队列的消息总数，调用方需持有m.rw
+func (m *priorityMailbox) queuedLocked() int {
+	return len(m.queues[0]) + len(m.queues[1]) + len(m.queues[2])
+}
+
+func (m *priorityMailbox) Post(ctx Context) {
+	m.rw.Lock()
+
+	level := m.priority(ctx)
+	if level < PriorityRequest || level > PrioritySystem {
+		level = PriorityRequest
+	}
+
+	for m.capacity > 0 && m.queuedLocked() >= m.capacity && !m.closed {
+		switch m.policy {
+		case OverflowDropNewest:
+			m.rw.Unlock()
+			m.dropped.Add(1)
+			return
+		case OverflowDropOldest:
+			if m.popOldestLocked() {
+				m.dropped.Add(1)
+			}
+		case OverflowDeadLetter:
+			var dead Context
+			if victim, ok := m.popOldestCtxLocked(); ok {
+				dead = victim
+			}
+			m.rw.Unlock()
+			if dead != nil {
+				m.deadLettered.Add(1)
+				if m.deadCh != nil {
+					m.deadCh <- dead
+				}
+			}
+			m.rw.Lock()
+		default: // OverflowBlock
+			// 阻塞等待run消费一条消息腾出空位，或Close唤醒后直接放弃投递
+			m.notFull.Wait()
+		}
+	}
+
+	if m.closed {
+		m.rw.Unlock()
+		return
+	}
+
+	m.queues[level] = append(m.queues[level], ctx)
+	m.depth.Add(1)
+	m.rw.Unlock()
+
+	m.wake()
+}
+
+// wake 唤醒阻塞在run中等待新消息的goroutine，notify已有待处理信号时不重复写入
+func (m *priorityMailbox) wake() {
+	select {
+	case m.notify <- struct{}{}:
+	default:
+	}
+}
+
+// popOldestLocked 丢弃当前最低优先级队列中最旧的一条消息，调用方需持有m.rw
+func (m *priorityMailbox) popOldestLocked() bool {
+	_, ok := m.popOldestCtxLocked()
+	return ok
+}
+
+func (m *priorityMailbox) popOldestCtxLocked() (Context, bool) {
+	for level := PriorityRequest; level <= PrioritySystem; level++ {
+		if len(m.queues[level]) > 0 {
+			ctx := m.queues[level][0]
+			m.queues[level] = m.queues[level][1:]
+			m.depth.Add(-1)
+			return ctx, true
+		}
+	}
+	return nil, false
+}
+
+// run 持续将优先级最高的消息推送到out，供dispatch循环消费。空闲时阻塞等待notify信号，
+// 不进行轮询；Close后既能令等待notify的run退出，也能令阻塞在out发送上的run退出
+func (m *priorityMailbox) run() {
+	for {
+		m.rw.Lock()
+		if m.closed {
+			m.rw.Unlock()
+			close(m.out)
+			return
+		}
+
+		var ctx Context
+		for level := PrioritySystem; level >= PriorityRequest; level-- {
+			if len(m.queues[level]) > 0 {
+				ctx = m.queues[level][0]
+				m.queues[level] = m.queues[level][1:]
+				break
+			}
+		}
+		if ctx != nil {
+			// 腾出了一个空位，唤醒可能阻塞在OverflowBlock策略下的Post
+			m.notFull.Broadcast()
+		}
+		m.rw.Unlock()
+
+		if ctx == nil {
+			select {
+			case <-m.notify:
+			case <-m.done:
+			}
+			continue
+		}
+
+		m.depth.Add(-1)
+
+		select {
+		case m.out <- ctx:
+		case <-m.done:
+			// 丢弃该消息，回到循环顶部，由m.closed分支负责关闭out并退出
+			continue
+		}
+	}
+}
+
+func (m *priorityMailbox) Next() <-chan Context {
+	return m.out
+}
+
+func (m *priorityMailbox) Done(start time.Time) {
+	m.totalLatency.Add(int64(time.Since(start)))
+	m.totalCount.Add(1)
+}
+
+func (m *priorityMailbox) Stats() MailboxStats {
+	count := m.totalCount.Load()
+	var avg time.Duration
+	if count > 0 {
+		avg = time.Duration(m.totalLatency.Load() / count)
+	}
+
+	return MailboxStats{
+		Depth:        m.depth.Load(),
+		Dropped:      m.dropped.Load(),
+		DeadLettered: m.deadLettered.Load(),
+		AvgLatency:   avg,
+	}
+}
+
+func (m *priorityMailbox) Close() {
+	m.rw.Lock()
+	if m.closed {
+		m.rw.Unlock()
+		return
+	}
+	m.closed = true
+	m.rw.Unlock()
+
+	close(m.done)
+	m.wake()
+	// 唤醒所有阻塞在OverflowBlock策略下等待空位的Post，使其感知到closed并放弃投递
+	m.notFull.Broadcast()
+}
@@ -0,0 +1,28 @@
+package node
+
+// ActorOption Actor配置项
+type ActorOption func(o *actorOptions)
+
+type actorOptions struct {
+	id              string         // 实例ID
+	mailboxCapacity int            // 邮箱容量，<=0表示不限制容量
+	mailboxPriority priorityFunc   // 邮箱优先级计算函数，为nil时使用默认规则
+	overflowPolicy  OverflowPolicy // 邮箱溢出策略
+	eventStore      EventStore     // 事件存储，仅PersistentProcessor使用
+	snapshotPolicy  SnapshotPolicy // 快照触发策略，仅PersistentProcessor使用
+}
+
+// WithMailboxCapacity 设置邮箱容量，超出容量后的行为由WithOverflowPolicy决定，默认不限制容量
+func WithMailboxCapacity(capacity int) ActorOption {
+	return func(o *actorOptions) { o.mailboxCapacity = capacity }
+}
+
+// WithMailboxPriority 设置邮箱消息优先级计算函数，数值越大越先被处理，默认区分系统/事件/请求三级
+func WithMailboxPriority(priority func(ctx Context) int) ActorOption {
+	return func(o *actorOptions) { o.mailboxPriority = priority }
+}
+
+// WithOverflowPolicy 设置邮箱容量溢出后的处理策略，默认阻塞等待
+func WithOverflowPolicy(policy OverflowPolicy) ActorOption {
+	return func(o *actorOptions) { o.overflowPolicy = policy }
+}
@@ -0,0 +1,195 @@
+package node
+
+import (
+	"context"
+	"fmt"
+	"github.com/dobyte/due/v2/log"
+	"time"
+)
+
+// PersistedEvent 事件溯源中的一条事件，Version在同一PersistenceID下单调递增
+type PersistedEvent struct {
+	Version uint64
+	Type    string
+	Data    any
+}
+
+// Snapshot 某个PersistenceID在某一Version时刻的状态快照
+type Snapshot struct {
+	Version uint64
+	Data    any
+}
+
+// EventStore 事件存储，Redis/etcd/MySQL等均可实现该接口接入持久化Actor
+type EventStore interface {
+	// Append 追加一条事件
+	Append(ctx context.Context, persistenceID string, event PersistedEvent) error
+	// Load 加载fromVersion（不含）之后的全部事件，按Version升序返回
+	Load(ctx context.Context, persistenceID string, fromVersion uint64) ([]PersistedEvent, error)
+	// SaveSnapshot 保存一次快照
+	SaveSnapshot(ctx context.Context, persistenceID string, snapshot Snapshot) error
+	// LoadSnapshot 加载最近一次快照，不存在时返回nil
+	LoadSnapshot(ctx context.Context, persistenceID string) (*Snapshot, error)
+}
+
+// PersistentProcessor 可选接口，Processor实现该接口后Actor在Spawn时会自动从EventStore
+// 恢复状态：先加载最近一次快照，再重放快照之后的全部事件，期间Actor邮箱不会被消费
+type PersistentProcessor interface {
+	Processor
+	// PersistenceID 持久化标识，同一标识下的事件与快照共享同一条时间线
+	PersistenceID() string
+	// ApplyEvent 将一条事件应用到当前状态，恢复期间与正常运行期间都会被调用
+	ApplyEvent(event PersistedEvent)
+	// Snapshot 生成当前状态的快照
+	Snapshot() (any, error)
+	// Recover 用快照中的数据恢复状态
+	Recover(snap any) error
+}
+
+// SnapshotPolicy 控制周期性快照的触发条件
+type SnapshotPolicy struct {
+	EveryNEvents int           // 每累计N个事件触发一次快照，<=0表示不按事件数触发
+	Interval     time.Duration // 每隔固定时间触发一次快照，<=0表示不按时间触发
+}
+
+// Persist 将event追加到EventStore，成功后在当前Actor线程上调用handler，并视配置的
+// SnapshotPolicy决定是否顺带生成一次新快照
+func (a *Actor) Persist(event PersistedEvent, handler func()) {
+	pp, ok := a.processor.(PersistentProcessor)
+	if !ok {
+		log.Errorf("actor %s: Persist called on a non-persistent processor", a.PID())
+		return
+	}
+
+	store := a.opts.eventStore
+	if store == nil {
+		log.Errorf("actor %s: Persist called without an EventStore configured", a.PID())
+		return
+	}
+
+	ctx := context.Background()
+
+	a.persistMu.Lock()
+	version := a.persistVersion + 1
+	a.persistMu.Unlock()
+
+	event.Version = version
+
+	if err := store.Append(ctx, pp.PersistenceID(), event); err != nil {
+		log.Errorf("actor %s: append event failed: %v", a.PID(), err)
+		return
+	}
+
+	a.persistMu.Lock()
+	a.persistVersion = version
+	a.persistMu.Unlock()
+
+	pp.ApplyEvent(event)
+
+	if handler != nil {
+		handler()
+	}
+
+	a.persistMu.Lock()
+	a.persistEventsSinceSnapshot++
+	due := a.opts.snapshotPolicy.EveryNEvents > 0 && a.persistEventsSinceSnapshot >= a.opts.snapshotPolicy.EveryNEvents
+	a.persistMu.Unlock()
+
+	if due {
+		a.snapshot(ctx, pp)
+	}
+}
+
+func (a *Actor) snapshot(ctx context.Context, pp PersistentProcessor) {
+	data, err := pp.Snapshot()
+	if err != nil {
+		log.Errorf("actor %s: build snapshot failed: %v", a.PID(), err)
+		return
+	}
+
+	a.persistMu.Lock()
+	version := a.persistVersion
+	a.persistMu.Unlock()
+
+	if err := a.opts.eventStore.SaveSnapshot(ctx, pp.PersistenceID(), Snapshot{Version: version, Data: data}); err != nil {
+		log.Errorf("actor %s: save snapshot failed: %v", a.PID(), err)
+		return
+	}
+
+	a.persistMu.Lock()
+	a.persistEventsSinceSnapshot = 0
+	a.persistMu.Unlock()
+}
+
+// recover 在dispatch循环启动前同步加载快照并重放后续事件，期间邮箱不会被消费。
+// 任何一步失败都会原样返回error而不是放过——让状态停留在空的/局部的时间线上启动，
+// 会被下一次Persist当作版本1写穿已有的事件日志，属于静默数据损坏，比拒绝启动更糟
+func (a *Actor) recover() error {
+	pp, ok := a.processor.(PersistentProcessor)
+	if !ok || a.opts.eventStore == nil {
+		return nil
+	}
+
+	ctx := context.Background()
+	store := a.opts.eventStore
+
+	snap, err := store.LoadSnapshot(ctx, pp.PersistenceID())
+	if err != nil {
+		return fmt.Errorf("load snapshot: %w", err)
+	}
+
+	fromVersion := uint64(0)
+
+	if snap != nil {
+		if err := pp.Recover(snap.Data); err != nil {
+			return fmt.Errorf("recover from snapshot: %w", err)
+		}
+		fromVersion = snap.Version
+	}
+
+	events, err := store.Load(ctx, pp.PersistenceID(), fromVersion)
+	if err != nil {
+		return fmt.Errorf("load events: %w", err)
+	}
+
+	for _, event := range events {
+		pp.ApplyEvent(event)
+	}
+
+	a.persistMu.Lock()
+	if len(events) > 0 {
+		a.persistVersion = events[len(events)-1].Version
+	} else {
+		a.persistVersion = fromVersion
+	}
+	a.persistMu.Unlock()
+
+	if a.opts.snapshotPolicy.Interval > 0 {
+		go a.periodicSnapshot(pp, a.opts.snapshotPolicy.Interval)
+	}
+
+	return nil
+}
+
+func (a *Actor) periodicSnapshot(pp PersistentProcessor, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if a.state.Load() != started {
+			return
+		}
+
+		a.Invoke(func() { a.snapshot(context.Background(), pp) })
+	}
+}
+
+// WithEventStore 设置该Actor使用的事件存储，仅对实现了PersistentProcessor的处理器生效
+func WithEventStore(store EventStore) ActorOption {
+	return func(o *actorOptions) { o.eventStore = store }
+}
+
+// WithSnapshotPolicy 设置周期性快照的触发条件
+func WithSnapshotPolicy(policy SnapshotPolicy) ActorOption {
+	return func(o *actorOptions) { o.snapshotPolicy = policy }
+}
@@ -10,19 +10,53 @@ import (
 )
 
 type Proxy struct {
-	node *Node      // 节点
-	link *link.Link // 链接
+	node        *Node           // 节点
+	link        *link.Link      // 链接
+	deadLetters *deadLetterSink // 节点级死信队列
+	gossip      *gossiper       // gossip成员发现与反熵传播器
 }
 
-func newProxy(node *Node) *Proxy {
-	return &Proxy{node: node, link: link.NewLink(&link.Options{
-		NID:         node.opts.id,
-		Codec:       node.opts.codec,
-		Locator:     node.opts.locator,
-		Registry:    node.opts.registry,
-		Encryptor:   node.opts.encryptor,
-		Transporter: node.opts.transporter,
-	})}
+func newProxy(node *Node, gossipOpts ...GossipOption) *Proxy {
+	return &Proxy{
+		node: node,
+		link: link.NewLink(&link.Options{
+			NID:         node.opts.id,
+			Codec:       node.opts.codec,
+			Locator:     node.opts.locator,
+			Registry:    node.opts.registry,
+			Encryptor:   node.opts.encryptor,
+			Transporter: node.opts.transporter,
+		}),
+		deadLetters: newDeadLetterSink(),
+		gossip:      newGossiper(node.opts.id, gossipOpts...),
+	}
+}
+
+// Members 获取gossip视角下当前已知的集群成员列表，未配置GossipTransport时仅反映最近一次
+// registry/locator冷启动拉取到的快照
+func (p *Proxy) Members() []Member {
+	return p.gossip.list()
+}
+
+// SubscribeMembership 订阅成员上线/suspect/dead等状态变更事件
+func (p *Proxy) SubscribeMembership(ch chan<- GossipEvent) {
+	p.gossip.subscribe(ch)
+}
+
+// PublishUserEvent 主动发布一条用户事件，随下一轮gossip piggyback到其他节点
+func (p *Proxy) PublishUserEvent(typ UserEventType, uid int64, key string) {
+	p.piggyback(typ, uid, key)
+}
+
+// piggyback 将一次绑定类操作记录为gossip增量事件
+func (p *Proxy) piggyback(typ UserEventType, uid int64, key string) {
+	p.gossip.publish(typ, uid, key)
+}
+
+// SubscribeDeadLetters 订阅节点级死信队列，当Actor邮箱按DeadLetter策略丢弃消息时会被推送到ch，
+// ch处理不及时时死信会被直接丢弃，不会反向阻塞邮箱
+func (p *Proxy) SubscribeDeadLetters(ch chan<- Context) {
+	p.deadLetters.subscribe(ch)
 }
 
 // GetNodeID 获取当前节点ID
@@ -65,34 +99,66 @@ func (p *Proxy) NewServiceClient(target string) (transport.ServiceClient, error)
 
 // BindGate 绑定网关
 func (p *Proxy) BindGate(ctx context.Context, uid int64, gid string, cid int64) error {
-	return p.link.BindGate(ctx, uid, gid, cid)
+	if err := p.link.BindGate(ctx, uid, gid, cid); err != nil {
+		return err
+	}
+
+	p.piggyback(UserBindGate, uid, gid)
+
+	return nil
 }
 
 // UnbindGate 解绑网关
 func (p *Proxy) UnbindGate(ctx context.Context, uid int64) error {
-	return p.link.UnbindGate(ctx, uid)
+	if err := p.link.UnbindGate(ctx, uid); err != nil {
+		return err
+	}
+
+	p.piggyback(UserUnbindGate, uid, "")
+
+	return nil
 }
 
 // BindNode 绑定节点
 // 单个用户可以绑定到多个节点服务器上，相同名称的节点服务器只能绑定一个，多次绑定会到相同名称的节点服务器会覆盖之前的绑定。
 // 绑定操作会通过发布订阅方式同步到网关服务器和其他相关节点服务器上。
 func (p *Proxy) BindNode(ctx context.Context, uid int64, nameAndNID ...string) error {
+	var name, nid string
+
 	if len(nameAndNID) >= 2 && nameAndNID[0] != "" && nameAndNID[1] != "" {
-		return p.link.BindNode(ctx, uid, nameAndNID[0], nameAndNID[1])
+		name, nid = nameAndNID[0], nameAndNID[1]
 	} else {
-		return p.link.BindNode(ctx, uid, p.node.opts.name, p.node.opts.id)
+		name, nid = p.node.opts.name, p.node.opts.id
+	}
+
+	if err := p.link.BindNode(ctx, uid, name, nid); err != nil {
+		return err
 	}
+
+	p.piggyback(UserBindNode, uid, name+"/"+nid)
+
+	return nil
 }
 
 // UnbindNode 解绑节点
 // 解绑时会对对应名称的节点服务器进行解绑，解绑时会对解绑节点ID进行校验，不匹配则解绑失败。
 // 解绑操作会通过发布订阅方式同步到网关服务器和其他相关节点服务器上。
 func (p *Proxy) UnbindNode(ctx context.Context, uid int64, nameAndNID ...string) error {
+	var name, nid string
+
 	if len(nameAndNID) >= 2 && nameAndNID[0] != "" && nameAndNID[1] != "" {
-		return p.link.UnbindNode(ctx, uid, nameAndNID[0], nameAndNID[1])
+		name, nid = nameAndNID[0], nameAndNID[1]
 	} else {
-		return p.link.UnbindNode(ctx, uid, p.node.opts.name, p.node.opts.id)
+		name, nid = p.node.opts.name, p.node.opts.id
+	}
+
+	if err := p.link.UnbindNode(ctx, uid, name, nid); err != nil {
+		return err
 	}
+
+	p.piggyback(UserUnbindNode, uid, name+"/"+nid)
+
+	return nil
 }
 
 // LocateGate 定位用户所在网关
@@ -100,6 +166,12 @@ func (p *Proxy) LocateGate(ctx context.Context, uid int64) (string, error) {
 	return p.link.LocateGate(ctx, uid)
 }
 
+// LocateGateByGossip 从gossip反熵同步得到的本地绑定视图中定位用户所在网关，不发起网络请求，
+// 结果可能滞后于registry/locator的权威数据，仅用于快速探测
+func (p *Proxy) LocateGateByGossip(uid int64) (string, bool) {
+	return p.gossip.locateGate(uid)
+}
+
 // AskGate 检测用户是否在给定的网关上
 func (p *Proxy) AskGate(ctx context.Context, uid int64, gid string) (string, bool, error) {
 	return p.link.AskGate(ctx, uid, gid)
@@ -110,6 +182,12 @@ func (p *Proxy) LocateNode(ctx context.Context, uid int64, name string) (string,
 	return p.link.LocateNode(ctx, uid, name)
 }
 
+// LocateNodeByGossip 从gossip反熵同步得到的本地绑定视图中定位用户所在节点，不发起网络请求，
+// 结果可能滞后于registry/locator的权威数据，仅用于快速探测
+func (p *Proxy) LocateNodeByGossip(uid int64, name string) (string, bool) {
+	return p.gossip.locateNode(uid, name)
+}
+
 // AskNode 检测用户是否在给定的节点上
 func (p *Proxy) AskNode(ctx context.Context, uid int64, name, nid string) (string, bool, error) {
 	return p.link.AskNode(ctx, uid, name, nid)
@@ -219,4 +297,27 @@ func (p *Proxy) watch(ctx context.Context) {
 	p.link.WatchUserLocate(ctx, cluster.Gate.String(), cluster.Node.String())
 
 	p.link.WatchServiceInstance(ctx, cluster.Gate.String(), cluster.Node.String())
+
+	p.seedGossip(ctx)
+
+	go p.gossip.run(ctx)
+}
+
+// seedGossip 用registry中已有的网关/节点实例为gossip成员列表提供冷启动快照
+func (p *Proxy) seedGossip(ctx context.Context) {
+	members := make([]Member, 0)
+
+	if gates, err := p.FetchGateList(ctx); err == nil {
+		for _, ins := range gates {
+			members = append(members, Member{NID: ins.ID, Name: ins.Name, State: cluster.State(ins.State)})
+		}
+	}
+
+	if nodes, err := p.FetchNodeList(ctx); err == nil {
+		for _, ins := range nodes {
+			members = append(members, Member{NID: ins.ID, Name: ins.Name, State: cluster.State(ins.State)})
+		}
+	}
+
+	p.gossip.seed(members)
 }
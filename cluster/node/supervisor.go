@@ -0,0 +1,62 @@
+package node
+
+import "time"
+
+// SupervisorStrategy 监督策略，决定子Actor发生异常时的处理方式
+type SupervisorStrategy int
+
+const (
+	// OneForOne 仅重启发生异常的Actor
+	OneForOne SupervisorStrategy = iota
+	// OneForAll 重启发生异常Actor的所有兄弟Actor
+	OneForAll
+	// RestartOnFailure 按指数退避策略重启发生异常的Actor，超过重启上限后停止
+	RestartOnFailure
+	// StopOnFailure 发生异常后直接销毁Actor，不再重启
+	StopOnFailure
+	// EscalateToParent 将异常上报给父Actor处理
+	EscalateToParent
+)
+
+// SupervisorOption 监督策略配置项
+type SupervisorOption func(o *supervisorOptions)
+
+type supervisorOptions struct {
+	strategy     SupervisorStrategy
+	maxRestarts  int           // 最大重启次数，超过后不再重启
+	minBackoff   time.Duration // 最小退避时间
+	maxBackoff   time.Duration // 最大退避时间
+}
+
+var defaultSupervisorOptions = supervisorOptions{
+	strategy:    OneForOne,
+	maxRestarts: 10,
+	minBackoff:  time.Millisecond * 100,
+	maxBackoff:  time.Second * 10,
+}
+
+// WithSupervisorStrategy 设置监督策略
+func WithSupervisorStrategy(strategy SupervisorStrategy) SupervisorOption {
+	return func(o *supervisorOptions) { o.strategy = strategy }
+}
+
+// WithMaxRestarts 设置最大重启次数，仅对RestartOnFailure策略生效
+func WithMaxRestarts(maxRestarts int) SupervisorOption {
+	return func(o *supervisorOptions) { o.maxRestarts = maxRestarts }
+}
+
+// WithBackoff 设置退避时间范围，对所有会触发重启的策略（OneForOne/OneForAll/RestartOnFailure）生效
+func WithBackoff(min, max time.Duration) SupervisorOption {
+	return func(o *supervisorOptions) { o.minBackoff = min; o.maxBackoff = max }
+}
+
+// backoff 计算第n次重启前需要等待的退避时间
+func (o *supervisorOptions) backoff(restarts int) time.Duration {
+	d := o.minBackoff << uint(restarts)
+
+	if d <= 0 || d > o.maxBackoff {
+		d = o.maxBackoff
+	}
+
+	return d
+}